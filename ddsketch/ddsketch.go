@@ -15,13 +15,19 @@ import (
 
 type DDSketch struct {
 	mapping.IndexMapping
-	store store.Store
+	store         store.Store
+	negativeStore store.Store
+	zeroCount     int32
 }
 
-func NewDDSketch(indexMapping mapping.IndexMapping, store store.Store) *DDSketch {
+// NewDDSketch constructs an empty DDSketch, tracking positive values in store and negative values
+// (by absolute value) in negativeStore. Values whose absolute value is below
+// indexMapping.MinIndexableValue(), including zero, are tracked separately in a dedicated counter.
+func NewDDSketch(indexMapping mapping.IndexMapping, store, negativeStore store.Store) *DDSketch {
 	return &DDSketch{
-		IndexMapping: indexMapping,
-		store:        store,
+		IndexMapping:  indexMapping,
+		store:         store,
+		negativeStore: negativeStore,
 	}
 }
 
@@ -36,7 +42,7 @@ func LogUnboundedDenseDDSketch(relativeAccuracy float64) (*DDSketch, error) {
 	if err != nil {
 		return nil, err
 	}
-	return NewDDSketch(indexMapping, store.NewDenseStore()), nil
+	return NewDDSketch(indexMapping, store.NewDenseStore(), store.NewDenseStore()), nil
 }
 
 // Constructs an instance of DDSketch that offers constant-time insertion and whose size grows until the
@@ -48,7 +54,7 @@ func LogCollapsingLowestDenseDDSketch(relativeAccuracy float64, maxNumBins int)
 	if err != nil {
 		return nil, err
 	}
-	return NewDDSketch(indexMapping, store.NewCollapsingLowestDenseStore(maxNumBins)), nil
+	return NewDDSketch(indexMapping, store.NewCollapsingLowestDenseStore(maxNumBins), store.NewCollapsingLowestDenseStore(maxNumBins)), nil
 }
 
 // Constructs an instance of DDSketch that offers constant-time insertion and whose size grows until the
@@ -60,7 +66,7 @@ func LogCollapsingHighestDenseDDSketch(relativeAccuracy float64, maxNumBins int)
 	if err != nil {
 		return nil, err
 	}
-	return NewDDSketch(indexMapping, store.NewCollapsingHighestDenseStore(maxNumBins)), nil
+	return NewDDSketch(indexMapping, store.NewCollapsingHighestDenseStore(maxNumBins), store.NewCollapsingHighestDenseStore(maxNumBins)), nil
 }
 
 // Adds a value to the sketch.
@@ -68,45 +74,104 @@ func (s *DDSketch) Add(value float64) error {
 	return s.AddWithCount(value, int32(1))
 }
 
-// Adds a value to the sketch with a int32 count.
+// Adds a value to the sketch with a int32 count. Positive values are routed to the positive
+// store, negative values (by absolute value) to the negative store, and values whose absolute
+// value falls below MinIndexableValue(), including zero, are tallied in a dedicated zero bucket.
 func (s *DDSketch) AddWithCount(value float64, count int32) error {
-	if value < s.MinIndexableValue() || value > s.MaxIndexableValue() {
-		return errors.New("input value is outside the range that is tracked by the sketch")
-	}
 	if count < 0 {
 		return errors.New("count cannot be negative")
 	}
+	if count == 0 {
+		return nil
+	}
 
-	s.store.AddWithCount(s.Index(value), count)
+	switch {
+	case value >= s.MinIndexableValue():
+		if value > s.MaxIndexableValue() {
+			return errors.New("input value is outside the range that is tracked by the sketch")
+		}
+		s.store.AddWithCount(s.Index(value), count)
+	case value <= -s.MinIndexableValue():
+		if -value > s.MaxIndexableValue() {
+			return errors.New("input value is outside the range that is tracked by the sketch")
+		}
+		s.negativeStore.AddWithCount(s.Index(-value), count)
+	default:
+		s.zeroCount += count
+	}
 	return nil
 }
 
+// Reset clears this sketch back to empty in place, so it can be reused without reallocating its
+// underlying stores.
+func (s *DDSketch) Reset() {
+	s.store.Clear()
+	s.negativeStore.Clear()
+	s.zeroCount = 0
+}
+
+// Release clears this sketch and, if provider can reclaim stores (see store.Recycler), returns
+// its stores to it. After a call to Release, s must not be used again.
+func (s *DDSketch) Release(provider store.Provider) {
+	if r, ok := provider.(store.Recycler); ok {
+		r.Put(s.store)
+		r.Put(s.negativeStore)
+	} else {
+		s.store.Clear()
+		s.negativeStore.Clear()
+	}
+	s.zeroCount = 0
+}
+
 // Return a (deep) copy of this sketch.
 func (s *DDSketch) Copy() *DDSketch {
 	return &DDSketch{
-		IndexMapping: s.IndexMapping,
-		store:        s.store.Copy(),
+		IndexMapping:  s.IndexMapping,
+		store:         s.store.Copy(),
+		negativeStore: s.negativeStore.Copy(),
+		zeroCount:     s.zeroCount,
 	}
 }
 
 // Return the value at the specified quantile. Return a non-nil error if the quantile is invalid
 // or if the sketch is empty.
 func (s *DDSketch) GetValueAtQuantile(quantile float64) (float64, error) {
-	key, err := s.GetIndexAtQuantile(quantile)
-	if err != nil {
-		return math.NaN(), err
+	if quantile < 0 || quantile > 1 {
+		return math.NaN(), errors.New("quantile must be between 0 and 1")
+	}
+
+	count := s.GetCount()
+	if count == 0 {
+		return math.NaN(), errors.New("no such element exists")
 	}
-	return s.Value(key), nil
+
+	rank := quantile * float64(count-1)
+
+	negativeCount := s.negativeStore.TotalCount()
+	if rank < float64(negativeCount) {
+		// Values closest to zero come first, so the rank within the negative store is reversed.
+		key := s.negativeStore.KeyAtRank(float64(negativeCount) - rank - 1)
+		return -s.Value(key), nil
+	}
+	rank -= float64(negativeCount)
+
+	if rank < float64(s.zeroCount) {
+		return 0, nil
+	}
+	rank -= float64(s.zeroCount)
+
+	return s.Value(s.store.KeyAtRank(rank)), nil
 }
 
-// Return the index at the specified quantile. Return a non-nil error if the quantile is invalid
-// or if the sketch is empty.
+// Return the index at the specified quantile among the positive values tracked by this sketch.
+// Return a non-nil error if the quantile is invalid or if the sketch has no positive value.
+// Negative values and the zero bucket are not accounted for; use GetValueAtQuantile for those.
 func (s *DDSketch) GetIndexAtQuantile(quantile float64) (int, error) {
 	if quantile < 0 || quantile > 1 {
 		return 0, errors.New("quantile must be between 0 and 1")
 	}
 
-	count := s.GetCount()
+	count := s.store.TotalCount()
 	if count == 0 {
 		return 0, errors.New("no such element exists")
 	}
@@ -131,27 +196,47 @@ func (s *DDSketch) GetValuesAtQuantiles(quantiles []float64) ([]float64, error)
 
 // Return the total number of values that have been added to this sketch.
 func (s *DDSketch) GetCount() int32 {
-	return s.store.TotalCount()
+	return s.store.TotalCount() + s.negativeStore.TotalCount() + s.zeroCount
 }
 
 // Return true iff no value has been added to this sketch.
 func (s *DDSketch) IsEmpty() bool {
-	return s.store.IsEmpty()
+	return s.store.IsEmpty() && s.negativeStore.IsEmpty() && s.zeroCount == 0
 }
 
 // Return the maximum value that has been added to this sketch. Return a non-nil error if the sketch
 // is empty.
 func (s *DDSketch) GetMaxValue() (float64, error) {
-	maxIndex, err := s.store.MaxIndex()
+	if !s.store.IsEmpty() {
+		maxIndex, err := s.store.MaxIndex()
+		if err != nil {
+			return math.NaN(), err
+		}
+		return s.Value(maxIndex), nil
+	}
+	if s.zeroCount > 0 {
+		return 0, nil
+	}
+	minIndex, err := s.negativeStore.MinIndex()
 	if err != nil {
 		return math.NaN(), err
 	}
-	return s.Value(maxIndex), nil
+	return -s.Value(minIndex), nil
 }
 
 // Return the minimum value that has been added to this sketch. Returns a non-nil error if the sketch
 // is empty.
 func (s *DDSketch) GetMinValue() (float64, error) {
+	if !s.negativeStore.IsEmpty() {
+		maxIndex, err := s.negativeStore.MaxIndex()
+		if err != nil {
+			return math.NaN(), err
+		}
+		return -s.Value(maxIndex), nil
+	}
+	if s.zeroCount > 0 {
+		return 0, nil
+	}
 	minIndex, err := s.store.MinIndex()
 	if err != nil {
 		return math.NaN(), err
@@ -166,10 +251,36 @@ func (s *DDSketch) MergeWith(other *DDSketch) error {
 		return errors.New("cannot merge sketches with different index mappings")
 	}
 	s.store.MergeWith(other.store)
+	s.negativeStore.MergeWith(other.negativeStore)
+	s.zeroCount += other.zeroCount
 	return nil
 }
 
-// Extract the bins from the store
+// Extract the bins from the store, negative store first (most negative to closest to zero), then
+// the zero bucket, then the positive store (closest to zero to highest). Negative-store bins are
+// yielded with their index negated and the zero bucket as a bin of index 0; since store indices
+// can themselves be zero or negative, the sign of an index alone does not tell the regions apart.
 func (s *DDSketch) Bins() <-chan store.Bin {
-	return s.store.Bins()
+	ch := make(chan store.Bin)
+	go func() {
+		defer close(ch)
+
+		// negativeStore.Bins() yields ascending index, i.e. ascending magnitude, i.e. descending
+		// (more and more negative) value; reverse it so the overall sequence is value-ascending.
+		var negativeBins []store.Bin
+		for bin := range s.negativeStore.Bins() {
+			negativeBins = append(negativeBins, bin)
+		}
+		for i := len(negativeBins) - 1; i >= 0; i-- {
+			ch <- store.Bin{Index: -negativeBins[i].Index, Count: negativeBins[i].Count}
+		}
+
+		if s.zeroCount > 0 {
+			ch <- store.Bin{Index: 0, Count: s.zeroCount}
+		}
+		for bin := range s.store.Bins() {
+			ch <- bin
+		}
+	}()
+	return ch
 }
@@ -0,0 +1,142 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2021 GraphMetrics
+
+package ddsketch
+
+import (
+	"testing"
+
+	"github.com/graphmetrics/sketches-go/ddsketch/store"
+)
+
+func TestDDSketchNegativeAndZeroValues(t *testing.T) {
+	s, err := LogUnboundedDenseDDSketch(0.01)
+	if err != nil {
+		t.Fatalf("LogUnboundedDenseDDSketch: %v", err)
+	}
+
+	for i := -100; i <= 100; i++ {
+		if err := s.Add(float64(i)); err != nil {
+			t.Fatalf("Add(%d): %v", i, err)
+		}
+	}
+
+	if got, want := s.GetCount(), int32(201); got != want {
+		t.Errorf("GetCount() = %d, want %d", got, want)
+	}
+
+	min, err := s.GetMinValue()
+	if err != nil {
+		t.Fatalf("GetMinValue: %v", err)
+	}
+	if min > -95 {
+		t.Errorf("GetMinValue() = %v, want a value close to -100", min)
+	}
+
+	max, err := s.GetMaxValue()
+	if err != nil {
+		t.Fatalf("GetMaxValue: %v", err)
+	}
+	if max < 95 {
+		t.Errorf("GetMaxValue() = %v, want a value close to 100", max)
+	}
+
+	median, err := s.GetValueAtQuantile(0.5)
+	if err != nil {
+		t.Fatalf("GetValueAtQuantile: %v", err)
+	}
+	if median < -5 || median > 5 {
+		t.Errorf("GetValueAtQuantile(0.5) = %v, want a value close to 0", median)
+	}
+}
+
+// binValue decodes a store.Bin yielded by DDSketch.Bins() back to an approximate represented
+// value, reading a negative index as a negative value, index 0 as zero and a positive index as a
+// positive value. That reading only holds when every value added has an absolute value of at
+// least 1, so that no store index is negative; bins of index 0 are decoded as 0.
+func binValue(s *DDSketch, bin store.Bin) float64 {
+	switch {
+	case bin.Index < 0:
+		return -s.IndexMapping.Value(-bin.Index)
+	case bin.Index == 0:
+		return 0
+	default:
+		return s.IndexMapping.Value(bin.Index)
+	}
+}
+
+func TestDDSketchBinsAreValueAscendingAcrossAllRegions(t *testing.T) {
+	s, err := LogUnboundedDenseDDSketch(0.01)
+	if err != nil {
+		t.Fatalf("LogUnboundedDenseDDSketch: %v", err)
+	}
+
+	for _, v := range []float64{-500, -10, -1, 0, 0, 1, 10, 500} {
+		if err := s.Add(v); err != nil {
+			t.Fatalf("Add(%v): %v", v, err)
+		}
+	}
+
+	var prev float64
+	first := true
+	n := 0
+	for bin := range s.Bins() {
+		v := binValue(s, bin)
+		if !first && v < prev {
+			t.Errorf("Bins() out of order: %v came after %v", v, prev)
+		}
+		prev = v
+		first = false
+		n++
+	}
+	if n == 0 {
+		t.Errorf("Bins() yielded no entries")
+	}
+}
+
+func TestDDSketchMergeWithAcrossNegativeValues(t *testing.T) {
+	a, err := LogUnboundedDenseDDSketch(0.01)
+	if err != nil {
+		t.Fatalf("LogUnboundedDenseDDSketch: %v", err)
+	}
+	b, err := LogUnboundedDenseDDSketch(0.01)
+	if err != nil {
+		t.Fatalf("LogUnboundedDenseDDSketch: %v", err)
+	}
+
+	for _, v := range []float64{-100, -50, -1} {
+		if err := a.Add(v); err != nil {
+			t.Fatalf("a.Add(%v): %v", v, err)
+		}
+	}
+	for _, v := range []float64{-10, 0, 20} {
+		if err := b.Add(v); err != nil {
+			t.Fatalf("b.Add(%v): %v", v, err)
+		}
+	}
+
+	if err := a.MergeWith(b); err != nil {
+		t.Fatalf("MergeWith: %v", err)
+	}
+
+	if got, want := a.GetCount(), int32(6); got != want {
+		t.Errorf("GetCount() = %d, want %d", got, want)
+	}
+
+	min, err := a.GetMinValue()
+	if err != nil {
+		t.Fatalf("GetMinValue: %v", err)
+	}
+	if min > -95 {
+		t.Errorf("GetMinValue() = %v, want a value close to -100", min)
+	}
+
+	max, err := a.GetMaxValue()
+	if err != nil {
+		t.Fatalf("GetMaxValue: %v", err)
+	}
+	if max < 19 {
+		t.Errorf("GetMaxValue() = %v, want a value close to 20", max)
+	}
+}
@@ -0,0 +1,121 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2021 GraphMetrics
+
+package mapping
+
+import (
+	"errors"
+	"math"
+
+	"github.com/graphmetrics/sketches-go/ddsketch/pb"
+)
+
+// MinExponentialScale and MaxExponentialScale bound the scales ExponentialMapping accepts, wide
+// enough to cover the range store.ExponentialStore downscales through in practice.
+const (
+	MinExponentialScale = -10
+	MaxExponentialScale = 20
+)
+
+// ExponentialMapping is a base-2 exponential IndexMapping compatible with the OpenTelemetry
+// exponential histogram data model: a value maps to the index i such that
+// 2^(i/2^scale) <= value < 2^((i+1)/2^scale). Positive scales give finer-grained buckets (better
+// relative accuracy), negative scales coarser ones (fewer buckets for the same value range).
+type ExponentialMapping struct {
+	scale       int
+	scaleFactor float64 // 2^scale, precomputed so Index/Value avoid repeating math.Ldexp
+	totalShift  int     // cumulative amount every Downscale has shifted scale by, since creation
+}
+
+// NewExponentialMapping constructs an ExponentialMapping for the given scale.
+func NewExponentialMapping(scale int) (*ExponentialMapping, error) {
+	if scale < MinExponentialScale || scale > MaxExponentialScale {
+		return nil, errors.New("mapping: scale is out of the supported range")
+	}
+	return &ExponentialMapping{
+		scale:       scale,
+		scaleFactor: math.Ldexp(1, scale),
+	}, nil
+}
+
+// Scale returns the scale this mapping currently operates at.
+func (m *ExponentialMapping) Scale() int {
+	return m.scale
+}
+
+// TotalShift returns the cumulative amount every Downscale call has shifted this mapping's scale
+// by since it was created. A single ExponentialMapping is often shared by more than one
+// store.ExponentialStore (e.g. the positive and negative stores of one DDSketch): TotalShift is
+// how a store notices that a sibling store sharing this mapping has forced a coarser scale since
+// it last synced, so it can catch its own bins up to match (see store.ExponentialStore) instead of
+// being silently left indexed under the old scale. It is a plain counter rather than a
+// subscribe/notify callback so that stores sharing a mapping (including ones produced by
+// ExponentialStore.Copy) never need to register with, or be torn down by, the mapping itself.
+func (m *ExponentialMapping) TotalShift() int {
+	return m.totalShift
+}
+
+// Downscale coarsens this mapping by shift, i.e. sets its scale to scale-shift. Every store built
+// against this mapping is responsible for catching up to the new scale itself, via TotalShift.
+func (m *ExponentialMapping) Downscale(shift int) {
+	m.scale -= shift
+	m.scaleFactor = math.Ldexp(1, m.scale)
+	m.totalShift += shift
+}
+
+func (m *ExponentialMapping) Equals(other IndexMapping) bool {
+	o, ok := other.(*ExponentialMapping)
+	return ok && o.scale == m.scale
+}
+
+// Index returns floor(log2(value) * 2^scale).
+//
+// For scale <= 0 (one bucket spans one or more whole octaves), this is computed purely from the
+// IEEE 754 exponent bits via math.Frexp, with no logarithm call at all: value == frac * 2^exp with
+// frac in [0.5, 1), so floor(log2(value)) == exp-1 exactly, and dividing that integer by the
+// power-of-two 2^(-scale) is an exact arithmetic right shift. This is the same split
+// OpenTelemetry's exponential histogram mapping uses (its "exponent" mapping for scale <= 0, vs.
+// "logarithm" mapping for scale > 0): a positive scale needs sub-octave precision that the
+// exponent bits alone can't provide, so that case falls back to math.Log2.
+func (m *ExponentialMapping) Index(value float64) int {
+	if m.scale <= 0 {
+		_, exp := math.Frexp(value)
+		return (exp - 1) >> uint(-m.scale)
+	}
+
+	log2Value := math.Log2(value)
+	index := log2Value * m.scaleFactor
+	if index >= 0 {
+		return int(index)
+	}
+	return int(index) - 1 // faster than math.Floor
+}
+
+// Value returns the centroid of the bucket at index, i.e. its lower edge scaled by
+// (1+relativeAccuracy) so that it is within relativeAccuracy of every value that bucket can hold,
+// the same correction LogarithmicMapping.Value applies.
+func (m *ExponentialMapping) Value(index int) float64 {
+	return math.Exp2(float64(index)/m.scaleFactor) * (1 + m.RelativeAccuracy())
+}
+
+func (m *ExponentialMapping) RelativeAccuracy() float64 {
+	base := math.Exp2(1 / m.scaleFactor)
+	return (base - 1) / (base + 1)
+}
+
+func (m *ExponentialMapping) MinIndexableValue() float64 {
+	return minNormalFloat64
+}
+
+func (m *ExponentialMapping) MaxIndexableValue() float64 {
+	return math.MaxFloat64 / 2
+}
+
+// ToIndexMappingProto returns the protobuf representation of this mapping.
+func (m *ExponentialMapping) ToIndexMappingProto() *pb.IndexMapping {
+	return &pb.IndexMapping{
+		Kind:  pb.IndexMappingKind_EXPONENTIAL,
+		Scale: int32(m.scale),
+	}
+}
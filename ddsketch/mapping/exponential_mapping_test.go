@@ -0,0 +1,46 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2021 GraphMetrics
+
+package mapping
+
+import (
+	"math"
+	"testing"
+)
+
+func TestExponentialMappingIndexMatchesFloorLog2ForNonPositiveScale(t *testing.T) {
+	for _, scale := range []int{0, -1, -3, -10} {
+		m, err := NewExponentialMapping(scale)
+		if err != nil {
+			t.Fatalf("NewExponentialMapping(%d): %v", scale, err)
+		}
+
+		for _, v := range []float64{1, 2, 3, 8, 1000, 1024, 1e6, 1e-3} {
+			got := m.Index(v)
+			want := int(math.Floor(math.Log2(v) * m.scaleFactor))
+			if got != want {
+				t.Errorf("scale=%d v=%v: Index() = %d, want %d", scale, v, got, want)
+			}
+		}
+	}
+}
+
+func TestExponentialMappingValueWithinRelativeAccuracy(t *testing.T) {
+	for _, scale := range []int{0, 5, 10} {
+		m, err := NewExponentialMapping(scale)
+		if err != nil {
+			t.Fatalf("NewExponentialMapping(%d): %v", scale, err)
+		}
+		ra := m.RelativeAccuracy()
+
+		for _, v := range []float64{1, 1000, 1e6} {
+			index := m.Index(v)
+			value := m.Value(index)
+			relErr := math.Abs(value-v) / v
+			if relErr > ra*(1+1e-9) {
+				t.Errorf("scale=%d v=%v: relative error %v exceeds advertised RelativeAccuracy() %v", scale, v, relErr, ra)
+			}
+		}
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"errors"
 	"fmt"
 	"math"
+
+	"github.com/graphmetrics/sketches-go/ddsketch/pb"
 )
 
 // An IndexMapping that is memory-optimal, that is to say that given a targeted relative accuracy, it
@@ -98,6 +100,22 @@ func (m *LogarithmicMapping) RelativeAccuracy() float64 {
 	return m.relativeAccuracy
 }
 
+// gamma returns the base of the exponential bucketing scheme this mapping implements, i.e. the
+// value such that Value(index+1) == Value(index) * gamma.
+func (m *LogarithmicMapping) gamma() float64 {
+	return math.Exp(1 / m.multiplier)
+}
+
+// ToIndexMappingProto returns the protobuf representation of this mapping.
+func (m *LogarithmicMapping) ToIndexMappingProto() *pb.IndexMapping {
+	return &pb.IndexMapping{
+		Kind:          pb.IndexMappingKind_LOGARITHMIC,
+		Gamma:         m.gamma(),
+		IndexOffset:   m.normalizedIndexOffset,
+		Interpolation: pb.Interpolation_NONE,
+	}
+}
+
 func (m *LogarithmicMapping) string() string {
 	var buffer bytes.Buffer
 	buffer.WriteString(fmt.Sprintf("relativeAccuracy: %v, multiplier: %v, normalizedIndexOffset: %v\n", m.relativeAccuracy, m.multiplier, m.normalizedIndexOffset))
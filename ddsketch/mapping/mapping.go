@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2020 Datadog, Inc. for original work
+// Copyright 2021 GraphMetrics for modifications
+
+package mapping
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/graphmetrics/sketches-go/ddsketch/pb"
+)
+
+const (
+	expOverflow      = 7.094361393031e+02 // The value at which math.Exp overflows
+	minNormalFloat64 = 2.2250738585072014e-308
+)
+
+// IndexMapping maps positive floating-point values into indexes, and backwards, that are used to
+// keep track of the bins of a DDSketch.
+type IndexMapping interface {
+	Equals(other IndexMapping) bool
+	Index(value float64) int
+	Value(index int) float64
+	MinIndexableValue() float64
+	MaxIndexableValue() float64
+	RelativeAccuracy() float64
+
+	// ToIndexMappingProto returns the protobuf representation of this mapping, so that it can be
+	// reconstructed by FromProto without prior knowledge of its concrete type.
+	ToIndexMappingProto() *pb.IndexMapping
+}
+
+// FromProto reconstructs the IndexMapping that was serialized into m.
+func FromProto(m *pb.IndexMapping) (IndexMapping, error) {
+	switch m.Kind {
+	case pb.IndexMappingKind_EXPONENTIAL:
+		return NewExponentialMapping(int(m.Scale))
+	case pb.IndexMappingKind_LOGARITHMIC:
+		switch m.Interpolation {
+		case pb.Interpolation_NONE:
+			return NewLogarithmicMappingWithGamma(m.Gamma, m.IndexOffset)
+		default:
+			return nil, fmt.Errorf("mapping: unsupported interpolation %d", m.Interpolation)
+		}
+	default:
+		return nil, fmt.Errorf("mapping: unsupported mapping kind %d", m.Kind)
+	}
+}
+
+func withinTolerance(x, y, tolerance float64) bool {
+	if x == 0 || y == 0 {
+		return math.Abs(x) <= tolerance && math.Abs(y) <= tolerance
+	}
+	return math.Abs(x-y) <= tolerance*math.Max(math.Abs(x), math.Abs(y))
+}
@@ -0,0 +1,283 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2021 GraphMetrics
+//
+// Hand-maintained encode/decode for the messages described in ddsketch.proto.
+
+package pb
+
+import "math"
+
+// Interpolation identifies the slope approximation used by a logarithm-like IndexMapping. This
+// library only ever produces NONE today; the other values exist so that sketches produced by
+// other DDSketch implementations can still be rejected explicitly rather than silently misread.
+type Interpolation int32
+
+const (
+	Interpolation_NONE      Interpolation = 0
+	Interpolation_LINEAR    Interpolation = 1
+	Interpolation_QUADRATIC Interpolation = 2
+	Interpolation_CUBIC     Interpolation = 3
+)
+
+// IndexMappingKind distinguishes the families of IndexMapping this module can produce: the
+// logarithm-like family (plain, linearly, quadratically or cubically interpolated, as carried by
+// Interpolation) versus the base-2 exponential family used by OpenTelemetry-compatible mappings
+// (carried by Scale).
+type IndexMappingKind int32
+
+const (
+	IndexMappingKind_LOGARITHMIC IndexMappingKind = 0
+	IndexMappingKind_EXPONENTIAL IndexMappingKind = 1
+)
+
+// IndexMapping is the wire representation of a mapping.IndexMapping.
+type IndexMapping struct {
+	Gamma         float64
+	IndexOffset   float64
+	Interpolation Interpolation
+	Kind          IndexMappingKind
+	Scale         int32
+}
+
+func (m *IndexMapping) Marshal() []byte {
+	var b []byte
+	b = appendFloat64Field(b, 1, m.Gamma)
+	b = appendFloat64Field(b, 2, m.IndexOffset)
+	b = appendInt32Field(b, 3, int32(m.Interpolation))
+	b = appendInt32Field(b, 4, int32(m.Kind))
+	b = appendSInt32Field(b, 5, m.Scale)
+	return b
+}
+
+func (m *IndexMapping) Unmarshal(b []byte) error {
+	for len(b) > 0 {
+		field, wireType, n, err := consumeTag(b)
+		if err != nil {
+			return err
+		}
+		b = b[n:]
+		switch field {
+		case 1:
+			v, n, err := consumeFixed64(b)
+			if err != nil {
+				return err
+			}
+			m.Gamma = math.Float64frombits(v)
+			b = b[n:]
+		case 2:
+			v, n, err := consumeFixed64(b)
+			if err != nil {
+				return err
+			}
+			m.IndexOffset = math.Float64frombits(v)
+			b = b[n:]
+		case 3:
+			v, n, err := consumeVarint(b)
+			if err != nil {
+				return err
+			}
+			m.Interpolation = Interpolation(int32(v))
+			b = b[n:]
+		case 4:
+			v, n, err := consumeVarint(b)
+			if err != nil {
+				return err
+			}
+			m.Kind = IndexMappingKind(int32(v))
+			b = b[n:]
+		case 5:
+			v, n, err := consumeVarint(b)
+			if err != nil {
+				return err
+			}
+			m.Scale = zigzagDecode(v)
+			b = b[n:]
+		default:
+			n, err := skipField(b, wireType)
+			if err != nil {
+				return err
+			}
+			b = b[n:]
+		}
+	}
+	return nil
+}
+
+// Store is the wire representation of a store.Store: a sparse map of bin index to bin count.
+type Store struct {
+	BinCounts map[int32]int32
+}
+
+func (s *Store) Marshal() []byte {
+	var b []byte
+	for index, count := range s.BinCounts {
+		var entry []byte
+		entry = appendInt32Field(entry, 1, index)
+		entry = appendInt32Field(entry, 2, count)
+		b = appendBytesField(b, 1, entry)
+	}
+	return b
+}
+
+func (s *Store) Unmarshal(b []byte) error {
+	s.BinCounts = map[int32]int32{}
+	for len(b) > 0 {
+		field, wireType, n, err := consumeTag(b)
+		if err != nil {
+			return err
+		}
+		b = b[n:]
+		switch field {
+		case 1:
+			length, n, err := consumeVarint(b)
+			if err != nil {
+				return err
+			}
+			b = b[n:]
+			if uint64(len(b)) < length {
+				return errTruncated
+			}
+			entry := b[:length]
+			b = b[length:]
+			var index, count int32
+			for len(entry) > 0 {
+				entryField, entryWireType, n, err := consumeTag(entry)
+				if err != nil {
+					return err
+				}
+				entry = entry[n:]
+				switch entryField {
+				case 1:
+					v, n, err := consumeVarint(entry)
+					if err != nil {
+						return err
+					}
+					index = int32(v)
+					entry = entry[n:]
+				case 2:
+					v, n, err := consumeVarint(entry)
+					if err != nil {
+						return err
+					}
+					count = int32(v)
+					entry = entry[n:]
+				default:
+					n, err := skipField(entry, entryWireType)
+					if err != nil {
+						return err
+					}
+					entry = entry[n:]
+				}
+			}
+			s.BinCounts[index] = count
+		default:
+			n, err := skipField(b, wireType)
+			if err != nil {
+				return err
+			}
+			b = b[n:]
+		}
+	}
+	return nil
+}
+
+// DDSketch is the wire representation of a ddsketch.DDSketch.
+type DDSketch struct {
+	Mapping        *IndexMapping
+	PositiveValues *Store
+	NegativeValues *Store
+	ZeroCount      int32
+	Count          int32
+}
+
+func (d *DDSketch) Marshal() ([]byte, error) {
+	var b []byte
+	if d.Mapping != nil {
+		b = appendBytesField(b, 1, d.Mapping.Marshal())
+	}
+	if d.PositiveValues != nil {
+		b = appendBytesField(b, 2, d.PositiveValues.Marshal())
+	}
+	if d.NegativeValues != nil {
+		b = appendBytesField(b, 4, d.NegativeValues.Marshal())
+	}
+	b = appendInt32Field(b, 5, d.ZeroCount)
+	b = appendInt32Field(b, 3, d.Count)
+	return b, nil
+}
+
+func (d *DDSketch) Unmarshal(b []byte) error {
+	for len(b) > 0 {
+		field, wireType, n, err := consumeTag(b)
+		if err != nil {
+			return err
+		}
+		b = b[n:]
+		switch field {
+		case 1:
+			length, n, err := consumeVarint(b)
+			if err != nil {
+				return err
+			}
+			b = b[n:]
+			if uint64(len(b)) < length {
+				return errTruncated
+			}
+			d.Mapping = &IndexMapping{}
+			if err := d.Mapping.Unmarshal(b[:length]); err != nil {
+				return err
+			}
+			b = b[length:]
+		case 2:
+			length, n, err := consumeVarint(b)
+			if err != nil {
+				return err
+			}
+			b = b[n:]
+			if uint64(len(b)) < length {
+				return errTruncated
+			}
+			d.PositiveValues = &Store{}
+			if err := d.PositiveValues.Unmarshal(b[:length]); err != nil {
+				return err
+			}
+			b = b[length:]
+		case 3:
+			v, n, err := consumeVarint(b)
+			if err != nil {
+				return err
+			}
+			d.Count = int32(v)
+			b = b[n:]
+		case 4:
+			length, n, err := consumeVarint(b)
+			if err != nil {
+				return err
+			}
+			b = b[n:]
+			if uint64(len(b)) < length {
+				return errTruncated
+			}
+			d.NegativeValues = &Store{}
+			if err := d.NegativeValues.Unmarshal(b[:length]); err != nil {
+				return err
+			}
+			b = b[length:]
+		case 5:
+			v, n, err := consumeVarint(b)
+			if err != nil {
+				return err
+			}
+			d.ZeroCount = int32(v)
+			b = b[n:]
+		default:
+			n, err := skipField(b, wireType)
+			if err != nil {
+				return err
+			}
+			b = b[n:]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2021 GraphMetrics
+
+// Package pb defines the wire format of this module's DDSketch protobuf schema (see
+// ddsketch.proto) and hand-rolled encode/decode helpers for it, so that this module does not
+// need to depend on protoc or a generated protobuf runtime.
+package pb
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+var (
+	errTruncated = errors.New("pb: truncated message")
+	errOverflow  = errors.New("pb: varint overflow")
+)
+
+func appendTag(b []byte, field int, wireType int) []byte {
+	return appendVarint(b, uint64(field)<<3|uint64(wireType))
+}
+
+func appendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func appendFixed64(b []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+func appendInt32Field(b []byte, field int, v int32) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendTag(b, field, wireVarint)
+	return appendVarint(b, uint64(uint32(v)))
+}
+
+func appendFloat64Field(b []byte, field int, f float64) []byte {
+	if f == 0 {
+		return b
+	}
+	b = appendTag(b, field, wireFixed64)
+	return appendFixed64(b, math.Float64bits(f))
+}
+
+func appendBytesField(b []byte, field int, payload []byte) []byte {
+	b = appendTag(b, field, wireBytes)
+	b = appendVarint(b, uint64(len(payload)))
+	return append(b, payload...)
+}
+
+// appendSInt32Field appends a zig-zag encoded signed varint field, for fields such as a scale
+// that are naturally negative as often as positive.
+func appendSInt32Field(b []byte, field int, v int32) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendTag(b, field, wireVarint)
+	return appendVarint(b, uint64(uint32((v<<1)^(v>>31))))
+}
+
+func zigzagDecode(v uint64) int32 {
+	u := uint32(v)
+	return int32(u>>1) ^ -int32(u&1)
+}
+
+func consumeVarint(b []byte) (uint64, int, error) {
+	var v uint64
+	var shift uint
+	for i, c := range b {
+		if shift >= 64 {
+			return 0, 0, errOverflow
+		}
+		v |= uint64(c&0x7f) << shift
+		if c < 0x80 {
+			return v, i + 1, nil
+		}
+		shift += 7
+	}
+	return 0, 0, errTruncated
+}
+
+func consumeFixed64(b []byte) (uint64, int, error) {
+	if len(b) < 8 {
+		return 0, 0, errTruncated
+	}
+	return binary.LittleEndian.Uint64(b), 8, nil
+}
+
+func consumeTag(b []byte) (field int, wireType int, n int, err error) {
+	v, n, err := consumeVarint(b)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return int(v >> 3), int(v & 0x7), n, nil
+}
+
+// skipField advances past a field's value given its wire type, for forwards-compatibility with
+// unknown fields.
+func skipField(b []byte, wireType int) (int, error) {
+	switch wireType {
+	case wireVarint:
+		_, n, err := consumeVarint(b)
+		return n, err
+	case wireFixed64:
+		_, n, err := consumeFixed64(b)
+		return n, err
+	case wireBytes:
+		length, n, err := consumeVarint(b)
+		if err != nil {
+			return 0, err
+		}
+		if uint64(len(b)-n) < length {
+			return 0, errTruncated
+		}
+		return n + int(length), nil
+	default:
+		return 0, errors.New("pb: unsupported wire type")
+	}
+}
@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2020 Datadog, Inc. for original work
+// Copyright 2021 GraphMetrics for modifications
+
+package ddsketch
+
+import (
+	"errors"
+
+	"github.com/graphmetrics/sketches-go/ddsketch/mapping"
+	"github.com/graphmetrics/sketches-go/ddsketch/pb"
+	"github.com/graphmetrics/sketches-go/ddsketch/store"
+)
+
+// Encode serializes this sketch using the protobuf schema described in pb/ddsketch.proto. Its
+// IndexMapping message keeps the Datadog sketches-go field numbers; its Store and DDSketch
+// messages do not follow the Datadog layout.
+func (s *DDSketch) Encode() ([]byte, error) {
+	return s.toProto().Marshal()
+}
+
+func (s *DDSketch) toProto() *pb.DDSketch {
+	return &pb.DDSketch{
+		Mapping:        s.IndexMapping.ToIndexMappingProto(),
+		PositiveValues: storeToProto(s.store),
+		NegativeValues: storeToProto(s.negativeStore),
+		ZeroCount:      s.zeroCount,
+		Count:          s.GetCount(),
+	}
+}
+
+func storeToProto(st store.Store) *pb.Store {
+	binCounts := make(map[int32]int32)
+	for bin := range st.Bins() {
+		binCounts[int32(bin.Index)] += bin.Count
+	}
+	return &pb.Store{BinCounts: binCounts}
+}
+
+// DecodeDDSketch deserializes a sketch that was produced by Encode. Decoding is store-agnostic:
+// bins are re-inserted
+// into whatever Store storeProvider hands back, so a sketch can be decoded into a Store
+// implementation different from the one it was encoded with. indexMapping is used as the sketch's
+// index mapping if the encoded bytes do not carry one of their own.
+func DecodeDDSketch(b []byte, storeProvider store.Provider, indexMapping mapping.IndexMapping) (*DDSketch, error) {
+	var sketchPB pb.DDSketch
+	if err := sketchPB.Unmarshal(b); err != nil {
+		return nil, err
+	}
+
+	im := indexMapping
+	if sketchPB.Mapping != nil {
+		decodedMapping, err := mapping.FromProto(sketchPB.Mapping)
+		if err != nil {
+			return nil, err
+		}
+		im = decodedMapping
+	}
+	if im == nil {
+		return nil, errors.New("ddsketch: no index mapping available to decode sketch")
+	}
+
+	positiveStore := storeProvider.New()
+	if err := decodeIntoStore(sketchPB.PositiveValues, positiveStore); err != nil {
+		return nil, err
+	}
+	negativeStore := storeProvider.New()
+	if err := decodeIntoStore(sketchPB.NegativeValues, negativeStore); err != nil {
+		return nil, err
+	}
+
+	return &DDSketch{
+		IndexMapping:  im,
+		store:         positiveStore,
+		negativeStore: negativeStore,
+		zeroCount:     sketchPB.ZeroCount,
+	}, nil
+}
+
+func decodeIntoStore(storePB *pb.Store, dest store.Store) error {
+	dest.Clear() // dest comes from an arbitrary Provider, which need not hand out empty stores.
+	if storePB == nil {
+		return nil
+	}
+	for index, count := range storePB.BinCounts {
+		dest.AddWithCount(int(index), count)
+	}
+	return nil
+}
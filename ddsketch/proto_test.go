@@ -0,0 +1,52 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2021 GraphMetrics
+
+package ddsketch
+
+import (
+	"math"
+	"testing"
+
+	"github.com/graphmetrics/sketches-go/ddsketch/store"
+)
+
+func TestEncodeDecodeRoundTripAcrossStoreTypes(t *testing.T) {
+	original, err := LogUnboundedDenseDDSketch(0.01)
+	if err != nil {
+		t.Fatalf("LogUnboundedDenseDDSketch: %v", err)
+	}
+	for i := 1; i <= 1000; i++ {
+		if err := original.Add(float64(i)); err != nil {
+			t.Fatalf("Add(%d): %v", i, err)
+		}
+	}
+
+	b, err := original.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	// Decode into a different Store implementation than the one original was built with, since
+	// decode is meant to be store-agnostic.
+	decoded, err := DecodeDDSketch(b, store.CollapsingLowestDenseStoreProvider{MaxNumBins: 2048}, original.IndexMapping)
+	if err != nil {
+		t.Fatalf("DecodeDDSketch: %v", err)
+	}
+
+	if got, want := decoded.GetCount(), original.GetCount(); got != want {
+		t.Errorf("GetCount() = %d, want %d", got, want)
+	}
+
+	originalMedian, err := original.GetValueAtQuantile(0.5)
+	if err != nil {
+		t.Fatalf("original.GetValueAtQuantile: %v", err)
+	}
+	decodedMedian, err := decoded.GetValueAtQuantile(0.5)
+	if err != nil {
+		t.Fatalf("decoded.GetValueAtQuantile: %v", err)
+	}
+	if math.Abs(decodedMedian-originalMedian)/originalMedian > 0.02 {
+		t.Errorf("decoded median %v too far from original %v", decodedMedian, originalMedian)
+	}
+}
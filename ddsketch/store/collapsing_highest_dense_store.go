@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2020 Datadog, Inc. for original work
+// Copyright 2021 GraphMetrics for modifications
+
+package store
+
+// CollapsingHighestDenseStore is a DenseStore that never grows past maxNumBins: once that many
+// bins would be needed to cover the observed range of indices, the highest indices are collapsed
+// into the highest bin that still fits, trading relative accuracy on the highest quantiles for a
+// bounded memory footprint.
+type CollapsingHighestDenseStore struct {
+	*DenseStore
+	maxNumBins  int
+	isCollapsed bool
+}
+
+func NewCollapsingHighestDenseStore(maxNumBins int) *CollapsingHighestDenseStore {
+	return &CollapsingHighestDenseStore{
+		DenseStore: NewDenseStore(),
+		maxNumBins: maxNumBins,
+	}
+}
+
+// CollapsingHighestDenseStoreProvider is a store.Provider that builds new, empty
+// CollapsingHighestDenseStore instances sharing the same maxNumBins.
+type CollapsingHighestDenseStoreProvider struct {
+	MaxNumBins int
+}
+
+func (p CollapsingHighestDenseStoreProvider) New() Store {
+	return NewCollapsingHighestDenseStore(p.MaxNumBins)
+}
+
+func (s *CollapsingHighestDenseStore) Add(index int) {
+	s.AddWithCount(index, 1)
+}
+
+func (s *CollapsingHighestDenseStore) AddWithCount(index int, count int32) {
+	if count == 0 {
+		return
+	}
+	index = s.normalize(index)
+	s.DenseStore.AddWithCount(index, count)
+}
+
+func (s *CollapsingHighestDenseStore) AddBin(bin Bin) {
+	if bin.IsEmpty() {
+		return
+	}
+	s.AddWithCount(bin.Index, bin.Count)
+}
+
+// normalize maps index to the index it should actually be stored at, collapsing the store's range
+// if storing index as-is would require more than maxNumBins bins.
+func (s *CollapsingHighestDenseStore) normalize(index int) int {
+	if s.isCollapsed && index > s.maxIndex {
+		return s.maxIndex
+	}
+
+	minIndex := s.minIndex
+	if s.IsEmpty() || index < minIndex {
+		minIndex = index
+	}
+	maxIndex := s.maxIndex
+	if s.IsEmpty() || index > maxIndex {
+		maxIndex = index
+	}
+
+	if maxIndex-minIndex+1 > s.maxNumBins {
+		s.collapse(minIndex)
+		if index > s.maxIndex {
+			return s.maxIndex
+		}
+	}
+	return index
+}
+
+// collapse shrinks the store's tracked range down to the lowest maxNumBins indices above
+// minIndex, summing every bin that falls outside of that range into its new highest bin.
+func (s *CollapsingHighestDenseStore) collapse(minIndex int) {
+	newMaxIndex := minIndex + s.maxNumBins - 1
+	var collapsedCount int32
+	for i, c := range s.bins {
+		if c == 0 {
+			continue
+		}
+		if idx := i + s.offset; idx > newMaxIndex {
+			collapsedCount += c
+			s.bins[i] = 0
+			s.count -= c
+		}
+	}
+	s.maxIndex = newMaxIndex
+	s.isCollapsed = true
+	if collapsedCount > 0 {
+		s.DenseStore.AddWithCount(newMaxIndex, collapsedCount)
+	}
+}
+
+func (s *CollapsingHighestDenseStore) Copy() Store {
+	return &CollapsingHighestDenseStore{
+		DenseStore:  s.DenseStore.Copy().(*DenseStore),
+		maxNumBins:  s.maxNumBins,
+		isCollapsed: s.isCollapsed,
+	}
+}
+
+// Clear resets this store back to empty, including the collapsed flag, so it can be reused
+// without reallocating.
+func (s *CollapsingHighestDenseStore) Clear() {
+	s.DenseStore.Clear()
+	s.isCollapsed = false
+}
+
+func (s *CollapsingHighestDenseStore) MergeWith(other Store) {
+	for bin := range other.Bins() {
+		s.AddBin(bin)
+	}
+}
@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2020 Datadog, Inc. for original work
+// Copyright 2021 GraphMetrics for modifications
+
+package store
+
+// CollapsingLowestDenseStore is a DenseStore that never grows past maxNumBins: once that many
+// bins would be needed to cover the observed range of indices, the lowest indices are collapsed
+// into the lowest bin that still fits, trading relative accuracy on the lowest quantiles for a
+// bounded memory footprint.
+type CollapsingLowestDenseStore struct {
+	*DenseStore
+	maxNumBins  int
+	isCollapsed bool
+}
+
+func NewCollapsingLowestDenseStore(maxNumBins int) *CollapsingLowestDenseStore {
+	return &CollapsingLowestDenseStore{
+		DenseStore: NewDenseStore(),
+		maxNumBins: maxNumBins,
+	}
+}
+
+// CollapsingLowestDenseStoreProvider is a store.Provider that builds new, empty
+// CollapsingLowestDenseStore instances sharing the same maxNumBins.
+type CollapsingLowestDenseStoreProvider struct {
+	MaxNumBins int
+}
+
+func (p CollapsingLowestDenseStoreProvider) New() Store {
+	return NewCollapsingLowestDenseStore(p.MaxNumBins)
+}
+
+func (s *CollapsingLowestDenseStore) Add(index int) {
+	s.AddWithCount(index, 1)
+}
+
+func (s *CollapsingLowestDenseStore) AddWithCount(index int, count int32) {
+	if count == 0 {
+		return
+	}
+	index = s.normalize(index)
+	s.DenseStore.AddWithCount(index, count)
+}
+
+func (s *CollapsingLowestDenseStore) AddBin(bin Bin) {
+	if bin.IsEmpty() {
+		return
+	}
+	s.AddWithCount(bin.Index, bin.Count)
+}
+
+// normalize maps index to the index it should actually be stored at, collapsing the store's range
+// if storing index as-is would require more than maxNumBins bins.
+func (s *CollapsingLowestDenseStore) normalize(index int) int {
+	if s.isCollapsed && index < s.minIndex {
+		return s.minIndex
+	}
+
+	maxIndex := s.maxIndex
+	if s.IsEmpty() || index > maxIndex {
+		maxIndex = index
+	}
+	minIndex := s.minIndex
+	if s.IsEmpty() || index < minIndex {
+		minIndex = index
+	}
+
+	if maxIndex-minIndex+1 > s.maxNumBins {
+		s.collapse(maxIndex)
+		if index < s.minIndex {
+			return s.minIndex
+		}
+	}
+	return index
+}
+
+// collapse shrinks the store's tracked range down to the highest maxNumBins indices below
+// maxIndex, summing every bin that falls outside of that range into its new lowest bin.
+func (s *CollapsingLowestDenseStore) collapse(maxIndex int) {
+	newMinIndex := maxIndex - s.maxNumBins + 1
+	var collapsedCount int32
+	for i, c := range s.bins {
+		if c == 0 {
+			continue
+		}
+		if idx := i + s.offset; idx < newMinIndex {
+			collapsedCount += c
+			s.bins[i] = 0
+			s.count -= c
+		}
+	}
+	s.minIndex = newMinIndex
+	s.isCollapsed = true
+	if collapsedCount > 0 {
+		s.DenseStore.AddWithCount(newMinIndex, collapsedCount)
+	}
+}
+
+func (s *CollapsingLowestDenseStore) Copy() Store {
+	return &CollapsingLowestDenseStore{
+		DenseStore:  s.DenseStore.Copy().(*DenseStore),
+		maxNumBins:  s.maxNumBins,
+		isCollapsed: s.isCollapsed,
+	}
+}
+
+// Clear resets this store back to empty, including the collapsed flag, so it can be reused
+// without reallocating.
+func (s *CollapsingLowestDenseStore) Clear() {
+	s.DenseStore.Clear()
+	s.isCollapsed = false
+}
+
+func (s *CollapsingLowestDenseStore) MergeWith(other Store) {
+	for bin := range other.Bins() {
+		s.AddBin(bin)
+	}
+}
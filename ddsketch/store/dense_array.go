@@ -0,0 +1,154 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2021 GraphMetrics
+
+package store
+
+import "math"
+
+// arrayLengthOverhead is the number of extra bins allocated on either side of a newly grown
+// denseArray backing array, to absorb small range extensions without triggering a reallocation.
+const arrayLengthOverhead = 64
+
+// denseArray is the contiguous (array-based) bin storage shared by DenseStore and
+// ExponentialStore: both keep a dense slice of counts covering every index they have seen, and
+// differ only in how they decide which index a value maps to and what happens when they would
+// otherwise grow without bound.
+type denseArray struct {
+	bins     []int32
+	count    int32
+	offset   int
+	minIndex int
+	maxIndex int
+}
+
+func newDenseArray() denseArray {
+	return denseArray{
+		minIndex: math.MaxInt32,
+		maxIndex: math.MinInt32,
+	}
+}
+
+// add inserts count at index, growing the backing array if needed. It does not check count != 0;
+// callers are expected to skip the call entirely for a zero count.
+func (a *denseArray) add(index int, count int32) {
+	a.extendRange(index, index)
+	a.bins[index-a.offset] += count
+	a.count += count
+}
+
+// extendRange grows the backing array, if needed, so that every index in [minIndex, maxIndex] can
+// be stored without a further reallocation.
+func (a *denseArray) extendRange(minIndex, maxIndex int) {
+	if a.IsEmpty() {
+		length := maxIndex - minIndex + 1 + arrayLengthOverhead
+		if len(a.bins) < length {
+			a.bins = make([]int32, length)
+		}
+		a.offset = minIndex - arrayLengthOverhead/2
+		a.minIndex = minIndex
+		a.maxIndex = maxIndex
+		return
+	}
+
+	newMin := a.minIndex
+	if minIndex < newMin {
+		newMin = minIndex
+	}
+	newMax := a.maxIndex
+	if maxIndex > newMax {
+		newMax = maxIndex
+	}
+
+	if newMin-a.offset >= 0 && newMax-a.offset < len(a.bins) {
+		a.minIndex = newMin
+		a.maxIndex = newMax
+		return
+	}
+
+	length := newMax - newMin + 1 + arrayLengthOverhead
+	newOffset := newMin - arrayLengthOverhead/2
+	newBins := make([]int32, length)
+	if a.offset >= newOffset {
+		copy(newBins[a.offset-newOffset:], a.bins)
+	} else {
+		// a collapse raised minIndex: the bins below newOffset are all zero.
+		copy(newBins, a.bins[newOffset-a.offset:])
+	}
+	a.bins = newBins
+	a.offset = newOffset
+	a.minIndex = newMin
+	a.maxIndex = newMax
+}
+
+func (a *denseArray) IsEmpty() bool {
+	return a.count == 0
+}
+
+func (a *denseArray) TotalCount() int32 {
+	return a.count
+}
+
+func (a *denseArray) MinIndex() (int, error) {
+	if a.IsEmpty() {
+		return 0, errEmptyStore
+	}
+	for i, c := range a.bins {
+		if c > 0 {
+			return i + a.offset, nil
+		}
+	}
+	return 0, errEmptyStore
+}
+
+func (a *denseArray) MaxIndex() (int, error) {
+	if a.IsEmpty() {
+		return 0, errEmptyStore
+	}
+	for i := len(a.bins) - 1; i >= 0; i-- {
+		if a.bins[i] > 0 {
+			return i + a.offset, nil
+		}
+	}
+	return 0, errEmptyStore
+}
+
+// KeyAtRank returns the index of the bin containing the element at the given rank, assuming that
+// bins are iterated in ascending order of index. rank is 0-indexed.
+func (a *denseArray) KeyAtRank(rank float64) int {
+	var cumulCount int32
+	for i, c := range a.bins {
+		cumulCount += c
+		if c > 0 && float64(cumulCount) > rank {
+			return i + a.offset
+		}
+	}
+	if a.IsEmpty() {
+		return 0
+	}
+	return a.maxIndex
+}
+
+func (a *denseArray) Bins() <-chan Bin {
+	ch := make(chan Bin)
+	go func() {
+		defer close(ch)
+		for i, c := range a.bins {
+			if c > 0 {
+				ch <- Bin{Index: i + a.offset, Count: c}
+			}
+		}
+	}()
+	return ch
+}
+
+// Clear zeros the backing array in place and resets the min/max sentinels, so it can be reused
+// without reallocating.
+func (a *denseArray) Clear() {
+	for i := range a.bins {
+		a.bins[i] = 0
+	}
+	a.count = 0
+	a.minIndex = math.MaxInt32
+	a.maxIndex = math.MinInt32
+}
@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2020 Datadog, Inc. for original work
+// Copyright 2021 GraphMetrics for modifications
+
+package store
+
+// DenseStore is a dynamically growing, contiguous (array-based) Store implementation. Insertion
+// and lookup are O(1), at the cost of memory proportional to the range of indices it has seen,
+// which makes it a good default for inputs whose range is known to be bounded.
+type DenseStore struct {
+	denseArray
+}
+
+// NewDenseStore constructs an empty DenseStore.
+func NewDenseStore() *DenseStore {
+	return &DenseStore{denseArray: newDenseArray()}
+}
+
+// DenseStoreProvider is a store.Provider that builds new, empty DenseStore instances.
+type DenseStoreProvider struct{}
+
+func (DenseStoreProvider) New() Store { return NewDenseStore() }
+
+func (s *DenseStore) Add(index int) {
+	s.AddWithCount(index, 1)
+}
+
+func (s *DenseStore) AddWithCount(index int, count int32) {
+	if count == 0 {
+		return
+	}
+	s.add(index, count)
+}
+
+func (s *DenseStore) AddBin(bin Bin) {
+	if bin.IsEmpty() {
+		return
+	}
+	s.AddWithCount(bin.Index, bin.Count)
+}
+
+func (s *DenseStore) MergeWith(other Store) {
+	for bin := range other.Bins() {
+		s.AddBin(bin)
+	}
+}
+
+func (s *DenseStore) Copy() Store {
+	bins := make([]int32, len(s.bins))
+	copy(bins, s.bins)
+	return &DenseStore{
+		denseArray: denseArray{
+			bins:     bins,
+			count:    s.count,
+			offset:   s.offset,
+			minIndex: s.minIndex,
+			maxIndex: s.maxIndex,
+		},
+	}
+}
@@ -0,0 +1,195 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2021 GraphMetrics
+
+package store
+
+import (
+	"github.com/graphmetrics/sketches-go/ddsketch/mapping"
+)
+
+// DefaultExponentialStoreMaxSize is the bin budget an ExponentialStore uses when WithMaxSize is
+// not passed to NewExponentialStore.
+const DefaultExponentialStoreMaxSize = 160
+
+// ExponentialStoreOption configures an ExponentialStore constructed by NewExponentialStore.
+type ExponentialStoreOption func(*ExponentialStore)
+
+// WithMaxSize caps the number of bins an ExponentialStore will hold before downscaling.
+func WithMaxSize(n int) ExponentialStoreOption {
+	return func(s *ExponentialStore) {
+		s.maxSize = n
+	}
+}
+
+// ExponentialStore is a Store bound to a mapping.ExponentialMapping. Unlike the collapsing dense
+// stores, it never discards data: whenever the observed index range would need more than maxSize
+// bins at the current scale, it downscales its mapping (and merges its own bins accordingly) by
+// just enough to fit, degrading resolution instead of accuracy at the tails. The mapping may be
+// shared with another ExponentialStore (e.g. the negative store of the same DDSketch); every
+// method that reads or writes s's bins calls catchUp first, which compares s against
+// m.TotalShift() and replays any downscale a sibling store has triggered since s last looked,
+// keeping every store sharing m valid under its current scale without m needing to hold a
+// reference to each of them.
+type ExponentialStore struct {
+	denseArray
+	mapping     *mapping.ExponentialMapping
+	maxSize     int
+	syncedShift int // m.TotalShift() as of the last time catchUp ran
+}
+
+// NewExponentialStore constructs an empty ExponentialStore that indexes through m, downscaling m
+// as needed to respect maxSize (DefaultExponentialStoreMaxSize unless overridden via
+// WithMaxSize). m may be shared with another ExponentialStore (e.g. the negative store of the same
+// DDSketch): see catchUp for how s stays in sync with downscales a sibling store triggers.
+func NewExponentialStore(m *mapping.ExponentialMapping, opts ...ExponentialStoreOption) *ExponentialStore {
+	s := &ExponentialStore{
+		denseArray:  newDenseArray(),
+		mapping:     m,
+		maxSize:     DefaultExponentialStoreMaxSize,
+		syncedShift: m.TotalShift(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.maxSize < 1 {
+		s.maxSize = 1
+	}
+	return s
+}
+
+// catchUp replays, via applyDownscale, any shift a sibling store sharing s.mapping has triggered
+// since s last synced. Every ExponentialStore method that reads or writes s's bins calls this
+// first, so that s never holds bins indexed under a scale s.mapping has moved on from.
+func (s *ExponentialStore) catchUp() {
+	if shift := s.mapping.TotalShift() - s.syncedShift; shift > 0 {
+		s.applyDownscale(shift)
+		s.syncedShift += shift
+	}
+}
+
+func (s *ExponentialStore) Add(index int) {
+	s.AddWithCount(index, 1)
+}
+
+func (s *ExponentialStore) AddWithCount(index int, count int32) {
+	if count == 0 {
+		return
+	}
+	s.catchUp()
+	if !s.IsEmpty() {
+		newMin, newMax := s.minIndex, s.maxIndex
+		if index < newMin {
+			newMin = index
+		}
+		if index > newMax {
+			newMax = index
+		}
+		if bucketsNeeded := newMax - newMin + 1; bucketsNeeded > s.maxSize {
+			shift := downscaleShift(newMin, newMax, s.maxSize)
+			s.mapping.Downscale(shift)
+			s.applyDownscale(shift)
+			s.syncedShift += shift
+			index >>= shift
+		}
+	}
+	s.add(index, count)
+}
+
+func (s *ExponentialStore) MinIndex() (int, error) {
+	s.catchUp()
+	return s.denseArray.MinIndex()
+}
+
+func (s *ExponentialStore) MaxIndex() (int, error) {
+	s.catchUp()
+	return s.denseArray.MaxIndex()
+}
+
+func (s *ExponentialStore) KeyAtRank(rank float64) int {
+	s.catchUp()
+	return s.denseArray.KeyAtRank(rank)
+}
+
+func (s *ExponentialStore) Bins() <-chan Bin {
+	s.catchUp()
+	return s.denseArray.Bins()
+}
+
+func (s *ExponentialStore) AddBin(bin Bin) {
+	if bin.IsEmpty() {
+		return
+	}
+	s.AddWithCount(bin.Index, bin.Count)
+}
+
+// downscaleShift finds the smallest shift >= 1 such that right-shifting both bounds by shift
+// brings the number of buckets needed within maxSize.
+func downscaleShift(minIndex, maxIndex, maxSize int) int {
+	for shift := 1; ; shift++ {
+		if (maxIndex>>shift)-(minIndex>>shift)+1 <= maxSize {
+			return shift
+		}
+	}
+}
+
+// applyDownscale right-shifts every stored bucket index by shift, merging any collided counts. It
+// is called from catchUp (to replay a shift a sibling store triggered) and from AddWithCount (to
+// apply a shift s itself just triggered).
+func (s *ExponentialStore) applyDownscale(shift int) {
+	merged := make(map[int]int32, len(s.bins))
+	newMin, newMax := 0, 0
+	first := true
+	for i, c := range s.bins {
+		if c == 0 {
+			continue
+		}
+		index := (i + s.offset) >> shift
+		merged[index] += c
+		if first || index < newMin {
+			newMin = index
+		}
+		if first || index > newMax {
+			newMax = index
+		}
+		first = false
+	}
+
+	s.bins = nil
+	s.offset = 0
+	s.count = 0
+	if first {
+		// merged is empty: nothing to re-insert.
+		s.denseArray = newDenseArray()
+		return
+	}
+	s.extendRange(newMin, newMax)
+	for index, c := range merged {
+		s.bins[index-s.offset] = c
+		s.count += c
+	}
+}
+
+func (s *ExponentialStore) MergeWith(other Store) {
+	for bin := range other.Bins() {
+		s.AddBin(bin)
+	}
+}
+
+func (s *ExponentialStore) Copy() Store {
+	s.catchUp()
+	bins := make([]int32, len(s.bins))
+	copy(bins, s.bins)
+	return &ExponentialStore{
+		denseArray: denseArray{
+			bins:     bins,
+			offset:   s.offset,
+			minIndex: s.minIndex,
+			maxIndex: s.maxIndex,
+			count:    s.count,
+		},
+		mapping:     s.mapping,
+		maxSize:     s.maxSize,
+		syncedShift: s.syncedShift,
+	}
+}
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2021 GraphMetrics
+
+package store
+
+import (
+	"testing"
+
+	"github.com/graphmetrics/sketches-go/ddsketch/mapping"
+)
+
+func TestExponentialStoreSharedMappingDownscalesSiblingInLockstep(t *testing.T) {
+	m, err := mapping.NewExponentialMapping(5)
+	if err != nil {
+		t.Fatalf("NewExponentialMapping: %v", err)
+	}
+
+	positive := NewExponentialStore(m)
+	negative := NewExponentialStore(m)
+
+	negative.Add(m.Index(100))
+
+	// Forcing the positive store to downscale should rescale negative's bins too, since they
+	// share m: if it didn't, negative's bin would still be indexed under the old scale while m
+	// (and thus Value) now interprets it under the new, coarser one, decoding to a wildly wrong
+	// value (or even -Inf) instead of staying close to 100.
+	for _, v := range []float64{1, 1e6} {
+		positive.Add(m.Index(v))
+	}
+	if m.Scale() >= 5 {
+		t.Fatalf("test setup did not force a downscale, got scale %d", m.Scale())
+	}
+
+	key, err := negative.MaxIndex()
+	if err != nil {
+		t.Fatalf("negative.MaxIndex(): %v", err)
+	}
+	if got := m.Value(key); got < 50 || got > 200 {
+		t.Errorf("after sibling downscale, negative store's bin decodes to %v, want a value close to 100", got)
+	}
+}
+
+func TestExponentialStoreCopyCatchesUpToLaterSiblingDownscale(t *testing.T) {
+	m, err := mapping.NewExponentialMapping(5)
+	if err != nil {
+		t.Fatalf("NewExponentialMapping: %v", err)
+	}
+
+	negative := NewExponentialStore(m)
+	negative.Add(m.Index(100))
+
+	// Copy does not register anything on m: repeatedly copying must not make m's future
+	// downscales any more expensive, and every copy (not just the original) must still catch up
+	// to a downscale a sibling triggers afterwards.
+	var copies []Store
+	for i := 0; i < 1000; i++ {
+		copies = append(copies, negative.Copy())
+	}
+
+	positive := NewExponentialStore(m)
+	for _, v := range []float64{1, 1e6} {
+		positive.Add(m.Index(v))
+	}
+	if m.Scale() >= 5 {
+		t.Fatalf("test setup did not force a downscale, got scale %d", m.Scale())
+	}
+
+	for _, c := range []Store{negative, copies[0], copies[len(copies)-1]} {
+		key, err := c.MaxIndex()
+		if err != nil {
+			t.Fatalf("MaxIndex(): %v", err)
+		}
+		if got := m.Value(key); got < 50 || got > 200 {
+			t.Errorf("after sibling downscale, store's bin decodes to %v, want a value close to 100", got)
+		}
+	}
+}
+
+func TestExponentialStoreMaxSizeBelowOneIsClamped(t *testing.T) {
+	m, err := mapping.NewExponentialMapping(10)
+	if err != nil {
+		t.Fatalf("NewExponentialMapping: %v", err)
+	}
+
+	s := NewExponentialStore(m, WithMaxSize(0))
+	for _, v := range []float64{1, 1000, 1_000_000} {
+		s.Add(m.Index(v))
+	}
+	if s.TotalCount() != 3 {
+		t.Errorf("TotalCount() = %d, want 3", s.TotalCount())
+	}
+}
@@ -0,0 +1,34 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2021 GraphMetrics
+
+package store
+
+import "sync"
+
+// PooledProvider is a Provider backed by a sync.Pool, for services that decode or reset many
+// sketches and want to avoid the GC pressure of allocating a fresh Store every time. Stores
+// returned to the pool via Put are cleared before being handed back out by New.
+type PooledProvider struct {
+	pool sync.Pool
+}
+
+// NewPooledProvider returns a PooledProvider that builds new stores via newStore whenever its
+// pool has none ready to reuse.
+func NewPooledProvider(newStore func() Store) *PooledProvider {
+	return &PooledProvider{
+		pool: sync.Pool{
+			New: func() interface{} { return newStore() },
+		},
+	}
+}
+
+func (p *PooledProvider) New() Store {
+	return p.pool.Get().(Store)
+}
+
+// Put clears s and returns it to the pool for a future New call to reuse.
+func (p *PooledProvider) Put(s Store) {
+	s.Clear()
+	p.pool.Put(s)
+}
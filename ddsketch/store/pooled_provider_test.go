@@ -0,0 +1,36 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2021 GraphMetrics
+
+package store
+
+import "testing"
+
+func TestPooledProviderReusesClearedStore(t *testing.T) {
+	p := NewPooledProvider(func() Store { return NewDenseStore() })
+
+	s := p.New()
+	s.Add(5)
+	s.Add(7)
+	if s.TotalCount() != 2 {
+		t.Fatalf("TotalCount() = %d, want 2", s.TotalCount())
+	}
+
+	p.Put(s)
+	if !s.IsEmpty() {
+		t.Errorf("store is not empty right after Put, want Clear to have run")
+	}
+
+	reused := p.New()
+	if reused != s {
+		t.Fatalf("New() returned a different store than the one just Put back; sync.Pool should have reused it")
+	}
+	if !reused.IsEmpty() {
+		t.Errorf("store returned by New() is not empty")
+	}
+
+	reused.Add(1)
+	if got := reused.TotalCount(); got != 1 {
+		t.Errorf("TotalCount() after reuse = %d, want 1 (stale counts from prior use were not cleared)", got)
+	}
+}
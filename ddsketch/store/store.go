@@ -0,0 +1,52 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2020 Datadog, Inc. for original work
+// Copyright 2021 GraphMetrics for modifications
+
+package store
+
+import "errors"
+
+var errEmptyStore = errors.New("store: no index is stored")
+
+// Bin represents the count of values that fall into a single index of a DDSketch.
+type Bin struct {
+	Index int
+	Count int32
+}
+
+func (b Bin) IsEmpty() bool {
+	return b.Count == 0
+}
+
+// Store is a dictionary from bin index to bin count, used to keep track of the distribution of
+// values that have been added to a DDSketch.
+type Store interface {
+	Add(index int)
+	AddWithCount(index int, count int32)
+	AddBin(bin Bin)
+	Bins() <-chan Bin
+	Copy() Store
+	IsEmpty() bool
+	MaxIndex() (int, error)
+	MinIndex() (int, error)
+	TotalCount() int32
+	KeyAtRank(rank float64) int
+	MergeWith(other Store)
+
+	// Clear resets the store back to empty in place, so it can be reused without reallocating.
+	Clear()
+}
+
+// Provider constructs new, empty Store instances. Serialization and pooling code depends only on
+// Provider so that it stays agnostic of the concrete Store implementation a sketch was built with.
+type Provider interface {
+	New() Store
+}
+
+// Recycler is implemented by Providers that can reclaim a Store they produced for reuse, such as
+// PooledProvider. Code that wants to recycle a store checks for this interface rather than
+// requiring every Provider to support it.
+type Recycler interface {
+	Put(Store)
+}
@@ -0,0 +1,171 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2021 GraphMetrics
+
+// Package histogram implements a fixed-bin streaming histogram in the style of BigML's
+// streaming histogram: a sorted list of (mean, count) bins capped at a configured size, which
+// merges its closest-mean pair of bins whenever a new value would push it over budget. Unlike
+// ddsketch.DDSketch, its memory footprint never depends on the range of values it sees, at the
+// cost of giving no relative-error guarantee. Bins are held in a skipList, with a gapHeap (see
+// skiplist.go) tracking every adjacent pair's mean gap, so that both inserting a bin and finding
+// the closest pair to merge run in expected O(log maxBins) rather than a linear scan.
+package histogram
+
+import "math"
+
+// Bin is one bucket of a Histogram: the mean of every value folded into it, and how many values
+// that is.
+type Bin struct {
+	Mean  float64
+	Count int64
+}
+
+// Histogram is a streaming histogram capped at maxBins bins.
+type Histogram struct {
+	list    *skipList
+	maxBins int
+}
+
+// New returns an empty Histogram that keeps at most maxBins bins.
+func New(maxBins int) *Histogram {
+	if maxBins < 1 {
+		maxBins = 1
+	}
+	return &Histogram{list: newSkipList(), maxBins: maxBins}
+}
+
+// Insert adds v as a new singleton bin, then merges the closest-mean pair of bins until the
+// histogram is back within its maxBins budget.
+func (h *Histogram) Insert(v float64) {
+	h.insertBin(Bin{Mean: v, Count: 1})
+	h.shrinkToMaxBins()
+}
+
+// Merge folds every bin of other into h.
+func (h *Histogram) Merge(other *Histogram) {
+	for _, b := range other.list.bins() {
+		h.insertBin(b)
+	}
+	h.shrinkToMaxBins()
+}
+
+func (h *Histogram) insertBin(b Bin) {
+	n := h.list.insert(b)
+	before, after := n.back, n.forward[0]
+	h.list.clearGap(before) // before and after were adjacent; n now sits between them
+	h.list.addGap(before, n)
+	h.list.addGap(n, after)
+}
+
+func (h *Histogram) shrinkToMaxBins() {
+	for h.list.size > h.maxBins && h.list.gaps.Len() > 0 {
+		h.mergeClosestPair()
+	}
+}
+
+// mergeClosestPair merges the adjacent pair of bins with the smallest mean gap into one, reusing
+// the left bin's node and removing the right one.
+func (h *Histogram) mergeClosestPair() {
+	if h.list.gaps.Len() == 0 {
+		return
+	}
+	closest := h.list.gaps[0]
+	left, right := closest.left, closest.right
+	before, after := left.back, right.forward[0]
+
+	// Clear every gap this merge invalidates before recomputing them against left's new mean:
+	// the pair being merged away, and both of its now-stale neighboring gaps.
+	h.list.clearGap(before)
+	h.list.clearGap(left)
+	h.list.clearGap(right)
+
+	count := left.Count + right.Count
+	left.Mean = (left.Mean*float64(left.Count) + right.Mean*float64(right.Count)) / float64(count)
+	left.Count = count
+	h.list.remove(right)
+
+	h.list.addGap(before, left)
+	h.list.addGap(left, after)
+}
+
+func (h *Histogram) totalCount(bins []Bin) float64 {
+	var total float64
+	for _, b := range bins {
+		total += float64(b.Count)
+	}
+	return total
+}
+
+// Sum estimates the number of inserted values that are <= x, using trapezoidal interpolation
+// between the pair of bins bracketing x.
+func (h *Histogram) Sum(x float64) float64 {
+	bins := h.list.bins()
+	n := len(bins)
+	if n == 0 {
+		return 0
+	}
+	if x < bins[0].Mean {
+		return 0
+	}
+	if x >= bins[n-1].Mean {
+		return h.totalCount(bins)
+	}
+
+	i := 0
+	for i < n-1 && bins[i+1].Mean <= x {
+		i++
+	}
+	pi, pi1 := bins[i].Mean, bins[i+1].Mean
+	mi, mi1 := float64(bins[i].Count), float64(bins[i+1].Count)
+
+	// mb is the interpolated bin density at x, assuming it varies linearly between the two
+	// bracketing bins; s is the trapezoidal area between pi and x under that density.
+	mb := mi + (mi1-mi)/(pi1-pi)*(x-pi)
+	s := (mi + mb) / 2 * (x - pi) / (pi1 - pi)
+
+	var prefix float64
+	for j := 0; j < i; j++ {
+		prefix += float64(bins[j].Count)
+	}
+	return prefix + mi/2 + s
+}
+
+// searchSum binary searches [lo, hi] for the value x such that Sum(x) == target, relying on Sum
+// being monotonic non-decreasing.
+func (h *Histogram) searchSum(target, lo, hi float64) float64 {
+	for i := 0; i < 64; i++ {
+		mid := (lo + hi) / 2
+		if h.Sum(mid) < target {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// Quantile returns the value x such that Sum(x) approximates q times the total inserted count.
+func (h *Histogram) Quantile(q float64) float64 {
+	bins := h.list.bins()
+	if len(bins) == 0 {
+		return math.NaN()
+	}
+	return h.searchSum(q*h.totalCount(bins), bins[0].Mean, bins[len(bins)-1].Mean)
+}
+
+// Uniform returns the n-1 split points that divide the histogram into n buckets of approximately
+// equal count.
+func (h *Histogram) Uniform(n int) []float64 {
+	bins := h.list.bins()
+	if len(bins) == 0 || n <= 1 {
+		return nil
+	}
+
+	total := h.totalCount(bins)
+	lo, hi := bins[0].Mean, bins[len(bins)-1].Mean
+	splits := make([]float64, 0, n-1)
+	for k := 1; k < n; k++ {
+		splits = append(splits, h.searchSum(total*float64(k)/float64(n), lo, hi))
+	}
+	return splits
+}
@@ -0,0 +1,22 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2021 GraphMetrics
+
+package histogram
+
+import "testing"
+
+func TestHistogramBoundedBins(t *testing.T) {
+	h := New(10)
+	for i := 0; i < 1000; i++ {
+		h.Insert(float64(i))
+	}
+
+	if h.list.size > 10 {
+		t.Errorf("h.list.size = %d, want <= 10", h.list.size)
+	}
+
+	if median := h.Quantile(0.5); median < 400 || median > 600 {
+		t.Errorf("Quantile(0.5) = %v, want a value close to 500", median)
+	}
+}
@@ -0,0 +1,181 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2021 GraphMetrics
+
+package histogram
+
+import (
+	"container/heap"
+	"math/rand"
+)
+
+const (
+	maxLevel  = 16
+	levelProb = 0.5
+)
+
+// node is one bin held in a skipList, ordered by (Mean, seq): seq is a per-list insertion
+// sequence number used purely as a tie-breaker so that bins with an equal Mean (which Insert
+// produces routinely) still have a well-defined position to search for and remove.
+type node struct {
+	Bin
+	seq      int64
+	forward  []*node
+	back     *node     // level-0 predecessor, for O(1) neighbor lookups
+	rightGap *gapEntry // this node's gap to forward[0], tracked in skipList.gaps; nil at the tail
+}
+
+func (n *node) lessThan(mean float64, seq int64) bool {
+	return n.Mean < mean || (n.Mean == mean && n.seq < seq)
+}
+
+// skipList is the ordered bin storage backing a Histogram. It supports expected O(log size)
+// insert and removal of a known node, and tracks a gapHeap of every adjacent pair's mean gap so
+// that Histogram can find its closest-mean pair to merge in expected O(log size) too, rather than
+// a linear scan.
+type skipList struct {
+	head    *node
+	level   int
+	size    int
+	nextSeq int64
+	gaps    gapHeap
+}
+
+func newSkipList() *skipList {
+	return &skipList{
+		head:  &node{forward: make([]*node, maxLevel)},
+		level: 1,
+	}
+}
+
+func (l *skipList) randomLevel() int {
+	lvl := 1
+	for lvl < maxLevel && rand.Float64() < levelProb {
+		lvl++
+	}
+	return lvl
+}
+
+// insert adds b as a new node and returns it. It leaves the gap entries untouched; the caller
+// fixes up those of the new node and its neighbors (see Histogram.insertBin).
+func (l *skipList) insert(b Bin) *node {
+	seq := l.nextSeq
+	l.nextSeq++
+
+	update := make([]*node, maxLevel)
+	cur := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for cur.forward[i] != nil && cur.forward[i].lessThan(b.Mean, seq) {
+			cur = cur.forward[i]
+		}
+		update[i] = cur
+	}
+
+	lvl := l.randomLevel()
+	if lvl > l.level {
+		for i := l.level; i < lvl; i++ {
+			update[i] = l.head
+		}
+		l.level = lvl
+	}
+
+	n := &node{Bin: b, seq: seq, forward: make([]*node, lvl)}
+	for i := 0; i < lvl; i++ {
+		n.forward[i] = update[i].forward[i]
+		update[i].forward[i] = n
+	}
+	n.back = update[0]
+	if n.forward[0] != nil {
+		n.forward[0].back = n
+	}
+	l.size++
+	return n
+}
+
+// remove unlinks target, which must currently be in the list, from every level.
+func (l *skipList) remove(target *node) {
+	update := make([]*node, l.level)
+	cur := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for cur.forward[i] != nil && cur.forward[i].lessThan(target.Mean, target.seq) {
+			cur = cur.forward[i]
+		}
+		update[i] = cur
+	}
+	for i := 0; i < l.level; i++ {
+		if update[i].forward[i] == target {
+			update[i].forward[i] = target.forward[i]
+		}
+	}
+	if target.forward[0] != nil {
+		target.forward[0].back = target.back
+	}
+	for l.level > 1 && l.head.forward[l.level-1] == nil {
+		l.level--
+	}
+	l.size--
+}
+
+// bins returns every bin in ascending Mean order.
+func (l *skipList) bins() []Bin {
+	bins := make([]Bin, 0, l.size)
+	for n := l.head.forward[0]; n != nil; n = n.forward[0] {
+		bins = append(bins, n.Bin)
+	}
+	return bins
+}
+
+// clearGap removes left's current right-gap entry from the heap, if it has one.
+func (l *skipList) clearGap(left *node) {
+	if left == l.head || left.rightGap == nil {
+		return
+	}
+	heap.Remove(&l.gaps, left.rightGap.heapIndex)
+	left.rightGap = nil
+}
+
+// addGap creates and pushes a fresh gap entry reflecting left and right's current means. left must
+// not already have a right-gap entry (see clearGap).
+func (l *skipList) addGap(left, right *node) {
+	if left == l.head || right == nil {
+		return
+	}
+	e := &gapEntry{left: left, right: right, gap: right.Mean - left.Mean}
+	heap.Push(&l.gaps, e)
+	left.rightGap = e
+}
+
+// gapEntry is one adjacent-pair gap tracked in a skipList's gaps heap.
+type gapEntry struct {
+	left, right *node
+	gap         float64
+	heapIndex   int
+}
+
+// gapHeap is a container/heap.Interface min-heap of gapEntry, ordered by gap size, so the
+// smallest-gap adjacent pair can be found in O(log size) instead of a linear scan.
+type gapHeap []*gapEntry
+
+func (h gapHeap) Len() int           { return len(h) }
+func (h gapHeap) Less(i, j int) bool { return h[i].gap < h[j].gap }
+func (h gapHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].heapIndex = i
+	h[j].heapIndex = j
+}
+
+func (h *gapHeap) Push(x interface{}) {
+	e := x.(*gapEntry)
+	e.heapIndex = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *gapHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	e.heapIndex = -1
+	*h = old[:n-1]
+	return e
+}
@@ -0,0 +1,185 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2021 GraphMetrics
+
+// Package quantile implements the Cormode/Korn/Muthukrishnan/Srivastava biased-quantiles
+// algorithm: a bounded-memory summary of a stream of float64 values that answers
+// epsilon-approximate quantile queries. It is a fallback for inputs whose value range is too
+// wide for ddsketch.DDSketch's relative-error guarantee to stay cheap in bin count.
+package quantile
+
+import (
+	"math"
+	"sort"
+)
+
+// Target pairs a quantile of interest with the relative rank error the Stream should guarantee
+// around it. A Stream built from several Targets gives each a tighter error bound near its own
+// quantile, at the cost of a looser bound elsewhere.
+type Target struct {
+	Quantile float64
+	Epsilon  float64
+}
+
+// tuple is a summary entry (v, g, delta): v is the entry's value, g is the number of ranks between
+// this entry and the previous one, and delta is the entry's maximum rank uncertainty, following
+// the notation of the CKMS paper.
+type tuple struct {
+	v     float64
+	g     int64
+	delta int64
+}
+
+// compressInterval is how many inserts Stream batches before compressing, trading a small amount
+// of unreclaimed memory for amortizing the O(n) compress pass.
+const compressInterval = 128
+
+// Stream computes epsilon-approximate quantiles over a stream of float64 values, in memory
+// proportional to the number of distinct (value, rank) summary entries rather than the full
+// input.
+type Stream struct {
+	targets        []Target
+	uniformEpsilon float64
+	tuples         []tuple
+	n              int64
+	inserts        int64
+}
+
+// NewTargeted returns a Stream that guarantees, for each Target, a relative rank error of at most
+// Epsilon around Quantile.
+func NewTargeted(targets ...Target) *Stream {
+	return &Stream{targets: targets}
+}
+
+// NewUniform returns a Stream that guarantees a relative rank error of at most epsilon at every
+// quantile.
+func NewUniform(epsilon float64) *Stream {
+	return &Stream{uniformEpsilon: epsilon}
+}
+
+// Count returns the number of values that have been inserted into this Stream.
+func (s *Stream) Count() int64 {
+	return s.n
+}
+
+// Insert adds v to the stream.
+func (s *Stream) Insert(v float64) {
+	s.insert(v, 1)
+	s.inserts++
+	if s.inserts%compressInterval == 0 {
+		s.compress()
+	}
+}
+
+// insert adds a tuple for weight colocated copies of v, generalizing Insert (which is just
+// insert(v, 1)) so that Merge can fold in another Stream's tuples directly, at their existing
+// weight, rather than replaying each one weight times.
+func (s *Stream) insert(v float64, weight int64) {
+	i := sort.Search(len(s.tuples), func(i int) bool { return s.tuples[i].v >= v })
+
+	var rMin int64
+	for _, t := range s.tuples[:i] {
+		rMin += t.g
+	}
+
+	// The current minimum and maximum are always kept exact (delta 0), so that Query never has
+	// to extrapolate past an observed extreme.
+	var delta int64
+	if i > 0 && i < len(s.tuples) {
+		delta = int64(math.Floor(s.invariant(float64(rMin)))) - weight + 1
+		if delta < 0 {
+			delta = 0
+		}
+	}
+
+	s.tuples = append(s.tuples, tuple{})
+	copy(s.tuples[i+1:], s.tuples[i:])
+	s.tuples[i] = tuple{v: v, g: weight, delta: delta}
+
+	s.n += weight
+}
+
+// invariant is f(r, n): the maximum total uncertainty (g+delta) a summary entry at rank r may
+// carry without violating any of the Stream's accuracy targets.
+func (s *Stream) invariant(r float64) float64 {
+	n := float64(s.n)
+	if len(s.targets) == 0 {
+		return 2 * s.uniformEpsilon * r
+	}
+
+	f := math.Inf(1)
+	for _, t := range s.targets {
+		var fi float64
+		if r >= t.Quantile*n {
+			fi = 2 * t.Epsilon * r / t.Quantile
+		} else {
+			fi = 2 * t.Epsilon * (n - r) / (1 - t.Quantile)
+		}
+		if fi < f {
+			f = fi
+		}
+	}
+	return f
+}
+
+// compress merges adjacent tuples bottom-up whenever doing so still respects the invariant,
+// reclaiming the memory of summary entries that turned out not to be needed for the accuracy
+// guarantees the Stream was built with.
+func (s *Stream) compress() {
+	if len(s.tuples) < 2 {
+		return
+	}
+
+	merged := make([]tuple, 0, len(s.tuples))
+	merged = append(merged, s.tuples[len(s.tuples)-1])
+	rank := s.n - s.tuples[len(s.tuples)-1].g
+
+	for i := len(s.tuples) - 2; i >= 1; i-- {
+		cur := s.tuples[i]
+		top := &merged[len(merged)-1]
+		if float64(cur.g+top.g+top.delta) <= s.invariant(float64(rank)) {
+			top.g += cur.g
+		} else {
+			merged = append(merged, cur)
+		}
+		rank -= cur.g
+	}
+	merged = append(merged, s.tuples[0])
+
+	for l, r := 0, len(merged)-1; l < r; l, r = l+1, r-1 {
+		merged[l], merged[r] = merged[r], merged[l]
+	}
+	s.tuples = merged
+}
+
+// Query returns the epsilon-approximate value at quantile q (0 <= q <= 1).
+func (s *Stream) Query(q float64) float64 {
+	if len(s.tuples) == 0 {
+		return math.NaN()
+	}
+
+	rank := q * float64(s.n)
+	threshold := rank + s.invariant(rank)/2
+
+	var prefix int64
+	for i, t := range s.tuples {
+		if float64(prefix+t.g+t.delta) > threshold {
+			if i == 0 {
+				return t.v
+			}
+			return s.tuples[i-1].v
+		}
+		prefix += t.g
+	}
+	return s.tuples[len(s.tuples)-1].v
+}
+
+// Merge folds other into s. Its cost is proportional to the number of summary entries other
+// holds, not the number of raw values folded into it, which is the whole point of a bounded-memory
+// summary for fan-in aggregation across many streams.
+func (s *Stream) Merge(other *Stream) {
+	for _, t := range other.tuples {
+		s.insert(t.v, t.g)
+	}
+	s.compress()
+}
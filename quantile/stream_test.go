@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License 2.0.
+// Copyright 2021 GraphMetrics
+
+package quantile
+
+import "testing"
+
+func TestStreamUniformQuery(t *testing.T) {
+	s := NewUniform(0.01)
+	for i := 1; i <= 100; i++ {
+		s.Insert(float64(i))
+	}
+
+	if got := s.Count(); got != 100 {
+		t.Errorf("Count() = %d, want 100", got)
+	}
+
+	if median := s.Query(0.5); median < 45 || median > 55 {
+		t.Errorf("Query(0.5) = %v, want a value close to 50", median)
+	}
+}
+
+func TestStreamCompressesPastInterval(t *testing.T) {
+	const n = 20 * compressInterval
+	s := NewUniform(0.01)
+	for i := 1; i <= n; i++ {
+		s.Insert(float64(i))
+	}
+
+	if got := s.Count(); got != n {
+		t.Errorf("Count() = %d, want %d", got, n)
+	}
+	if len(s.tuples) >= n {
+		t.Errorf("len(s.tuples) = %d, want well under %d (compress never ran)", len(s.tuples), n)
+	}
+
+	if median := s.Query(0.5); median < float64(n)*0.45 || median > float64(n)*0.55 {
+		t.Errorf("Query(0.5) = %v, want a value close to %v", median, float64(n)/2)
+	}
+}
+
+func TestStreamTargeted(t *testing.T) {
+	s := NewTargeted(Target{Quantile: 0.99, Epsilon: 0.001})
+	for i := 1; i <= 10000; i++ {
+		s.Insert(float64(i))
+	}
+
+	if p99 := s.Query(0.99); p99 < 9900 || p99 > 9910 {
+		t.Errorf("Query(0.99) = %v, want a value close to 9900", p99)
+	}
+}
+
+func TestStreamMerge(t *testing.T) {
+	a := NewUniform(0.01)
+	for i := 1; i <= 5000; i++ {
+		a.Insert(float64(i))
+	}
+	b := NewUniform(0.01)
+	for i := 5001; i <= 10000; i++ {
+		b.Insert(float64(i))
+	}
+
+	a.Merge(b)
+
+	if got, want := a.Count(), int64(10000); got != want {
+		t.Errorf("Count() = %d, want %d", got, want)
+	}
+	if median := a.Query(0.5); median < 4750 || median > 5250 {
+		t.Errorf("Query(0.5) = %v, want a value close to 5000", median)
+	}
+}